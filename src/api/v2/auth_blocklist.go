@@ -0,0 +1,46 @@
+package api
+
+import (
+	"sync"
+	"time"
+)
+
+// BlocklistStore 记录已吊销的令牌,供AuthMiddleware在校验时查询
+type BlocklistStore interface {
+	// Revoke 将jti标记为吊销,直到until过期后可以被清理
+	Revoke(jti string, until time.Time) error
+	// IsRevoked 判断jti当前是否处于吊销状态
+	IsRevoked(jti string) bool
+}
+
+// InMemoryBlocklistStore 是BlocklistStore的进程内实现,适用于单实例部署与测试
+type InMemoryBlocklistStore struct {
+	mu      sync.Mutex
+	revoked map[string]time.Time
+}
+
+// NewInMemoryBlocklistStore 创建一个空的内存黑名单
+func NewInMemoryBlocklistStore() *InMemoryBlocklistStore {
+	return &InMemoryBlocklistStore{revoked: make(map[string]time.Time)}
+}
+
+func (s *InMemoryBlocklistStore) Revoke(jti string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.revoked[jti] = until
+	return nil
+}
+
+func (s *InMemoryBlocklistStore) IsRevoked(jti string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	until, ok := s.revoked[jti]
+	if !ok {
+		return false
+	}
+	if time.Now().After(until) {
+		delete(s.revoked, jti)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ZZYYG/aicr-test-medium-repo/src/apierr"
+)
+
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// AccessTokenTTL 是签发的访问令牌的默认有效期
+const AccessTokenTTL = 15 * time.Minute
+
+// RefreshTokenTTL 是签发的刷新令牌的默认有效期
+const RefreshTokenTTL = 7 * 24 * time.Hour
+
+// Authenticator 校验登录凭据并返回对应的身份信息
+type Authenticator interface {
+	Authenticate(username, password string) (*Principal, error)
+}
+
+// LoginRequest 是/auth/login的请求体
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// RefreshRequest 是/auth/refresh的请求体
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TokenPairResponse 是登录与刷新成功后返回的令牌对
+type TokenPairResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// AuthHandler 处理登录、刷新与登出请求
+type AuthHandler struct {
+	issuer        TokenIssuer
+	blocklist     BlocklistStore
+	authenticator Authenticator
+}
+
+// NewAuthHandler 创建认证处理器
+func NewAuthHandler(issuer TokenIssuer, blocklist BlocklistStore, authenticator Authenticator) *AuthHandler {
+	return &AuthHandler{issuer: issuer, blocklist: blocklist, authenticator: authenticator}
+}
+
+// RegisterRoutes 注册/auth下的路由
+func (h *AuthHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/auth/login", h.Login).Methods("POST")
+	router.HandleFunc("/auth/refresh", h.Refresh).Methods("POST")
+	router.HandleFunc("/auth/logout", h.Logout).Methods("POST")
+}
+
+func (h *AuthHandler) issueTokenPair(principal Principal) (*TokenPairResponse, error) {
+	access, err := h.issuer.Issue(principal, AccessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	refreshPrincipal := principal
+	refreshPrincipal.Scopes = append(append([]string{}, principal.Scopes...), "refresh")
+	refresh, err := h.issuer.Issue(refreshPrincipal, RefreshTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPairResponse{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		ExpiresIn:    int(AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+// Login 校验用户名密码并签发令牌对
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierr.WriteError(w, apierr.NewValidationError([]apierr.FieldError{{Field: "_", Reason: "请求体不是合法的JSON"}}))
+		return
+	}
+
+	principal, err := h.authenticator.Authenticate(req.Username, req.Password)
+	if err != nil || principal == nil {
+		apierr.WriteError(w, apierr.ErrUnauthorized)
+		return
+	}
+
+	pair, err := h.issueTokenPair(*principal)
+	if err != nil {
+		apierr.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pair)
+}
+
+// Refresh 用刷新令牌换取新的令牌对,并吊销旧的刷新令牌
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierr.WriteError(w, apierr.NewValidationError([]apierr.FieldError{{Field: "_", Reason: "请求体不是合法的JSON"}}))
+		return
+	}
+
+	claims, err := h.issuer.Parse(req.RefreshToken)
+	if err != nil {
+		apierr.WriteError(w, apierr.ErrUnauthorized)
+		return
+	}
+
+	if !hasScope(claims.Scopes, "refresh") {
+		apierr.WriteError(w, apierr.ErrUnauthorized)
+		return
+	}
+
+	if h.blocklist != nil && h.blocklist.IsRevoked(claims.ID) {
+		apierr.WriteError(w, apierr.ErrUnauthorized)
+		return
+	}
+
+	if h.blocklist != nil {
+		h.blocklist.Revoke(claims.ID, claims.ExpiresAt.Time)
+	}
+
+	pair, err := h.issueTokenPair(Principal{UserID: claims.Subject, Roles: claims.Roles})
+	if err != nil {
+		apierr.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pair)
+}
+
+// Logout 将当前访问令牌加入黑名单
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	token := bearerToken(r)
+	claims, err := h.issuer.Parse(token)
+	if err != nil {
+		apierr.WriteError(w, apierr.ErrUnauthorized)
+		return
+	}
+
+	if h.blocklist != nil {
+		h.blocklist.Revoke(claims.ID, claims.ExpiresAt.Time)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ZZYYG/aicr-test-medium-repo/src/apierr"
+)
+
+type contextKey string
+
+const principalContextKey contextKey = "api.principal"
+
+// Principal 表示一个已通过认证的调用者
+type Principal struct {
+	UserID string
+	Roles  []string
+	Scopes []string
+}
+
+// PrincipalFromContext 从请求上下文中取出AuthMiddleware注入的身份信息
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalContextKey).(Principal)
+	return p, ok
+}
+
+func (p Principal) hasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (p Principal) hasAnyRole(roles []string) bool {
+	if len(roles) == 0 {
+		return true
+	}
+	for _, role := range roles {
+		if p.hasRole(role) {
+			return true
+		}
+	}
+	return false
+}
+
+// RouteRequirement 描述访问某个路由所需满足的条件
+type RouteRequirement struct {
+	// Roles 列出允许访问的角色;Roles为空且AllowSelf为false时任意已登录用户均可访问,
+	// Roles为空且AllowSelf为true时只有访问自身{id}的调用者可以访问
+	Roles []string
+	// AllowSelf 允许路径中的{id}与调用者自身ID相同时放行,无需命中Roles
+	AllowSelf bool
+}
+
+// AuthPolicy 按 "METHOD /path模板" 描述每个路由的认证与鉴权要求
+type AuthPolicy struct {
+	Requirements map[string]RouteRequirement
+}
+
+// RequirementFor 返回指定路由的要求,未配置时视为仅需登录
+func (p AuthPolicy) RequirementFor(method, routeTemplate string) RouteRequirement {
+	if p.Requirements == nil {
+		return RouteRequirement{}
+	}
+	return p.Requirements[method+" "+routeTemplate]
+}
+
+// AuthMiddleware 校验请求携带的JWT Bearer令牌并执行RBAC鉴权
+type AuthMiddleware struct {
+	issuer    TokenIssuer
+	blocklist BlocklistStore
+	policy    AuthPolicy
+}
+
+// NewAuthMiddleware 创建认证中间件
+func NewAuthMiddleware(issuer TokenIssuer, blocklist BlocklistStore, policy AuthPolicy) *AuthMiddleware {
+	return &AuthMiddleware{issuer: issuer, blocklist: blocklist, policy: policy}
+}
+
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if !strings.HasPrefix(header, prefix) {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(header, prefix))
+}
+
+// Wrap 用认证与鉴权逻辑包裹next,routeTemplate为mux注册时使用的路径模板(如"/users/{id}")
+func (m *AuthMiddleware) Wrap(method, routeTemplate string, next http.HandlerFunc) http.HandlerFunc {
+	requirement := m.policy.RequirementFor(method, routeTemplate)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := bearerToken(r)
+		if token == "" {
+			apierr.WriteError(w, apierr.ErrUnauthorized)
+			return
+		}
+
+		claims, err := m.issuer.Parse(token)
+		if err != nil {
+			apierr.WriteError(w, apierr.ErrUnauthorized)
+			return
+		}
+
+		if m.blocklist != nil && m.blocklist.IsRevoked(claims.ID) {
+			apierr.WriteError(w, apierr.ErrUnauthorized)
+			return
+		}
+
+		principal := Principal{UserID: claims.Subject, Roles: claims.Roles, Scopes: claims.Scopes}
+
+		selfAccess := requirement.AllowSelf && mux.Vars(r)["id"] == principal.UserID
+		roleMatch := len(requirement.Roles) > 0 && principal.hasAnyRole(requirement.Roles)
+		noRequirement := len(requirement.Roles) == 0 && !requirement.AllowSelf
+
+		if !(roleMatch || selfAccess || noRequirement) {
+			apierr.WriteError(w, apierr.ErrForbidden)
+			return
+		}
+
+		if box, ok := r.Context().Value(principalBoxContextKey).(*principalBox); ok && box != nil {
+			box.principal = &principal
+		}
+
+		ctx := context.WithValue(r.Context(), principalContextKey, principal)
+		next(w, r.WithContext(ctx))
+	}
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func newTestMiddleware(policy AuthPolicy) (*AuthMiddleware, *HS256Issuer) {
+	issuer := NewHS256Issuer([]byte("test-secret"))
+	return NewAuthMiddleware(issuer, nil, policy), issuer
+}
+
+func doWrapped(t *testing.T, m *AuthMiddleware, method, routeTemplate, path, token string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	handler := m.Wrap(method, routeTemplate, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	router := mux.NewRouter()
+	router.HandleFunc(routeTemplate, handler).Methods(method)
+
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAuthMiddlewareWrapSelfAccessWithoutRoles(t *testing.T) {
+	policy := AuthPolicy{Requirements: map[string]RouteRequirement{
+		"GET /users/{id}": {AllowSelf: true},
+	}}
+	m, issuer := newTestMiddleware(policy)
+
+	selfToken, err := issuer.Issue(Principal{UserID: "user-A"}, AccessTokenTTL)
+	if err != nil {
+		t.Fatalf("签发令牌失败: %v", err)
+	}
+
+	if rec := doWrapped(t, m, "GET", "/users/{id}", "/users/user-A", selfToken); rec.Code != http.StatusOK {
+		t.Fatalf("访问自己的记录应当放行,得到状态码 %d", rec.Code)
+	}
+
+	// 回归用例:AllowSelf为true且Roles为空时,访问他人记录必须被拒绝——
+	// 这正是hasAnyRole(nil)恒为true导致的IDOR漏洞场景
+	if rec := doWrapped(t, m, "GET", "/users/{id}", "/users/user-B", selfToken); rec.Code != http.StatusForbidden {
+		t.Fatalf("访问他人记录应当被拒绝,得到状态码 %d", rec.Code)
+	}
+}
+
+func TestAuthMiddlewareWrapRoleMatch(t *testing.T) {
+	policy := AuthPolicy{Requirements: map[string]RouteRequirement{
+		"DELETE /users/{id}": {Roles: []string{"admin"}},
+	}}
+	m, issuer := newTestMiddleware(policy)
+
+	adminToken, _ := issuer.Issue(Principal{UserID: "user-A", Roles: []string{"admin"}}, AccessTokenTTL)
+	userToken, _ := issuer.Issue(Principal{UserID: "user-A"}, AccessTokenTTL)
+
+	if rec := doWrapped(t, m, "DELETE", "/users/{id}", "/users/user-B", adminToken); rec.Code != http.StatusOK {
+		t.Fatalf("管理员应当可以删除任意用户,得到状态码 %d", rec.Code)
+	}
+	if rec := doWrapped(t, m, "DELETE", "/users/{id}", "/users/user-B", userToken); rec.Code != http.StatusForbidden {
+		t.Fatalf("非管理员删除他人记录应当被拒绝,得到状态码 %d", rec.Code)
+	}
+}
+
+func TestAuthMiddlewareWrapNoRequirementAllowsAnyAuthenticatedUser(t *testing.T) {
+	m, issuer := newTestMiddleware(AuthPolicy{})
+
+	token, _ := issuer.Issue(Principal{UserID: "user-A"}, AccessTokenTTL)
+
+	if rec := doWrapped(t, m, "GET", "/status", "/status", token); rec.Code != http.StatusOK {
+		t.Fatalf("未配置Requirement的路由应当只需登录即可访问,得到状态码 %d", rec.Code)
+	}
+}
+
+func TestAuthMiddlewareWrapRejectsMissingToken(t *testing.T) {
+	m, _ := newTestMiddleware(AuthPolicy{})
+
+	if rec := doWrapped(t, m, "GET", "/status", "/status", ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("缺少令牌应当返回401,得到状态码 %d", rec.Code)
+	}
+}
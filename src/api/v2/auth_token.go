@@ -0,0 +1,108 @@
+package api
+
+import (
+	"crypto/rsa"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidToken 表示令牌缺失、格式错误、签名不合法或已过期
+var ErrInvalidToken = errors.New("无效的令牌")
+
+// ErrTokenRevoked 表示令牌已被加入黑名单
+var ErrTokenRevoked = errors.New("令牌已被吊销")
+
+// Claims 是签发给调用者的JWT声明,携带角色与权限范围
+type Claims struct {
+	jwt.RegisteredClaims
+	Roles  []string `json:"roles,omitempty"`
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// TokenIssuer 负责签发与解析访问令牌,允许不同的签名算法实现
+type TokenIssuer interface {
+	// Issue 为给定身份签发一个有效期为ttl的令牌
+	Issue(principal Principal, ttl time.Duration) (string, error)
+	// Parse 校验令牌签名与有效期,返回其中携带的声明
+	Parse(token string) (*Claims, error)
+}
+
+func newClaims(principal Principal, ttl time.Duration) Claims {
+	now := time.Now()
+	return Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   principal.UserID,
+			ID:        principal.UserID + ":" + now.Format(time.RFC3339Nano),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+		Roles:  principal.Roles,
+		Scopes: principal.Scopes,
+	}
+}
+
+// HS256Issuer 使用对称密钥签发/校验HS256令牌
+type HS256Issuer struct {
+	secret []byte
+}
+
+// NewHS256Issuer 创建基于共享密钥的令牌签发器
+func NewHS256Issuer(secret []byte) *HS256Issuer {
+	return &HS256Issuer{secret: secret}
+}
+
+func (i *HS256Issuer) Issue(principal Principal, ttl time.Duration) (string, error) {
+	claims := newClaims(principal, ttl)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(i.secret)
+}
+
+func (i *HS256Issuer) Parse(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrInvalidToken
+		}
+		return i.secret, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// RS256Issuer 使用RSA密钥对签发/校验RS256令牌,适合多服务共享公钥校验的场景
+type RS256Issuer struct {
+	privateKey *rsa.PrivateKey
+	publicKey  *rsa.PublicKey
+}
+
+// NewRS256Issuer 创建基于RSA密钥对的令牌签发器
+func NewRS256Issuer(privateKey *rsa.PrivateKey, publicKey *rsa.PublicKey) *RS256Issuer {
+	return &RS256Issuer{privateKey: privateKey, publicKey: publicKey}
+}
+
+func (i *RS256Issuer) Issue(principal Principal, ttl time.Duration) (string, error) {
+	if i.privateKey == nil {
+		return "", errors.New("缺少私钥,无法签发令牌")
+	}
+	claims := newClaims(principal, ttl)
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	return token.SignedString(i.privateKey)
+}
+
+func (i *RS256Issuer) Parse(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, ErrInvalidToken
+		}
+		return i.publicKey, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
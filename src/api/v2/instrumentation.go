@@ -0,0 +1,169 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+var (
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "http_request_duration_seconds",
+		Help: "HTTP请求处理耗时(秒),按路由/方法/状态码分桶",
+	}, []string{"route", "method", "status"})
+
+	httpRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP请求总数,按路由/方法/状态码计数",
+	}, []string{"route", "method", "status"})
+)
+
+func init() {
+	prometheus.MustRegister(httpRequestDuration, httpRequestsTotal)
+}
+
+// Pinger 由存储层实现,供/readyz探测其依赖(如数据库连接)是否可用
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+type principalBoxKey struct{}
+
+var principalBoxContextKey = principalBoxKey{}
+
+// principalBox是一个可以在请求处理期间被下游中间件写入的槽位。AuthMiddleware.Wrap在鉴权通过后把
+// Principal写入这里,instrumentationMiddleware在next.ServeHTTP返回之后读取,从而拿到"从auth ctx来的"
+// user_id用于日志——这比直接从ctx读取更可靠,因为Wrap创建的子context不会反向传播给外层中间件。
+type principalBox struct {
+	principal *Principal
+}
+
+// InstrumentedRouterOptions 配置NewInstrumentedRouter组合的可观测性中间件
+type InstrumentedRouterOptions struct {
+	ServiceName string
+	Logger      *zap.Logger
+	Tracer      trace.Tracer
+	Pinger      Pinger
+}
+
+// NewInstrumentedRouter 创建一个套有Prometheus指标、结构化日志与OpenTelemetry链路追踪中间件的mux.Router,
+// 并注册/metrics、/healthz与/readyz,其余路由由调用方继续通过RegisterRoutes挂载
+func NewInstrumentedRouter(opts InstrumentedRouterOptions) *mux.Router {
+	router := mux.NewRouter()
+
+	tracer := opts.Tracer
+	if tracer == nil {
+		tracer = otel.Tracer(opts.ServiceName)
+	}
+	logger := opts.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	router.Use(instrumentationMiddleware(tracer, logger))
+
+	router.Handle("/metrics", promhttp.Handler()).Methods("GET")
+
+	router.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}).Methods("GET")
+
+	router.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if opts.Pinger != nil {
+			if err := opts.Pinger.Ping(r.Context()); err != nil {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	}).Methods("GET")
+
+	return router
+}
+
+// responseRecorder记录状态码与写出的字节数,供指标与日志复用同一次遍历
+type responseRecorder struct {
+	http.ResponseWriter
+	status   int
+	bytesOut int64
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(b)
+	r.bytesOut += int64(n)
+	return n, err
+}
+
+func instrumentationMiddleware(tracer trace.Tracer, logger *zap.Logger) mux.MiddlewareFunc {
+	propagator := otel.GetTextMapPropagator()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			route := routeTemplate(r)
+
+			ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := tracer.Start(ctx, r.Method+" "+route)
+			defer span.End()
+
+			// AuthMiddleware.Wrap runs further down the chain and derives its own child context to carry
+			// the Principal; that child context is never visible here once next.ServeHTTP returns (Go
+			// contexts don't propagate upward). Sharing this box lets AuthMiddleware hand the principal
+			// back up without us needing to read the (inaccessible) child context.
+			box := &principalBox{}
+			ctx = context.WithValue(ctx, principalBoxContextKey, box)
+			span.SetAttributes(
+				semconv.HTTPMethodKey.String(r.Method),
+				semconv.HTTPRouteKey.String(route),
+			)
+
+			recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+
+			duration := time.Since(start)
+			status := strconv.Itoa(recorder.status)
+			httpRequestDuration.WithLabelValues(route, r.Method, status).Observe(duration.Seconds())
+			httpRequestsTotal.WithLabelValues(route, r.Method, status).Inc()
+			span.SetAttributes(semconv.HTTPStatusCodeKey.Int(recorder.status))
+
+			var userID string
+			if box.principal != nil {
+				userID = box.principal.UserID
+			}
+
+			logger.Info("http_request",
+				zap.String("request_id", r.Header.Get("X-Request-Id")),
+				zap.String("user_id", userID),
+				zap.String("route", route),
+				zap.String("method", r.Method),
+				zap.Int("status", recorder.status),
+				zap.Duration("latency", duration),
+				zap.Int64("bytes_out", recorder.bytesOut),
+			)
+		})
+	}
+}
+
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if tmpl, err := route.GetPathTemplate(); err == nil {
+			return tmpl
+		}
+	}
+	return r.URL.Path
+}
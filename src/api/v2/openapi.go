@@ -0,0 +1,150 @@
+package api
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ZZYYG/aicr-test-medium-repo/src/openapi"
+	"github.com/ZZYYG/aicr-test-medium-repo/src/query"
+)
+
+// Info 是OpenAPI文档的顶层元信息
+type Info = openapi.Info
+
+var pathParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+type operationDescriptor struct {
+	summary     string
+	requestBody string // 引用的schema名,空表示无请求体
+	security    []string
+	responses   map[string]openapi.Response
+}
+
+// operationDescriptors按"METHOD 路径模板"索引,只描述mux路由表里推导不出来的内容——人类撰写的摘要与
+// 响应码。路径、HTTP方法与路径参数一律通过RegisterOpenAPI里的router.Walk从实际注册的路由推导,
+// 不再手写第二份路由表,避免像之前那样和真实路由悄悄脱节
+var operationDescriptors = map[string]operationDescriptor{
+	"POST /users": {
+		summary:     "创建新用户",
+		requestBody: "UserRequest",
+		security:    []string{"bearerAuth"},
+		responses: map[string]openapi.Response{
+			"201": {Description: "创建成功", SchemaRef: "UserResponse"},
+			"400": {Description: "请求参数校验失败"},
+		},
+	},
+	"GET /users/{id}": {
+		summary:  "获取用户详情",
+		security: []string{"bearerAuth"},
+		responses: map[string]openapi.Response{
+			"200": {Description: "查询成功", SchemaRef: "UserResponse"},
+			"404": {Description: "资源不存在"},
+		},
+	},
+	"PUT /users/{id}": {
+		summary:     "更新用户",
+		requestBody: "UserRequest",
+		security:    []string{"bearerAuth"},
+		responses: map[string]openapi.Response{
+			"200": {Description: "更新成功", SchemaRef: "UserResponse"},
+			"404": {Description: "资源不存在"},
+		},
+	},
+	"DELETE /users/{id}": {
+		summary:  "删除用户",
+		security: []string{"bearerAuth"},
+		responses: map[string]openapi.Response{
+			"204": {Description: "删除成功"},
+			"404": {Description: "资源不存在"},
+		},
+	},
+	"GET /users": {
+		summary:  "分页列出用户,支持游标分页、排序、过滤与全文检索",
+		security: []string{"bearerAuth"},
+		responses: map[string]openapi.Response{
+			"200": {Description: "查询成功", SchemaRef: "UserPage"},
+		},
+	},
+}
+
+// listUsersQueryParams为GET /users生成查询参数。排序/过滤字段直接复用query包导出的白名单,
+// 而不是手写一份很快就会和Builder实际接受的参数脱节的列表
+func listUsersQueryParams() []openapi.Parameter {
+	params := []openapi.Parameter{
+		{Name: "cursor", In: "query", Schema: openapi.Schema{Type: "string"}},
+		{Name: "limit", In: "query", Schema: openapi.Schema{Type: "integer"}},
+		{Name: "q", In: "query", Schema: openapi.Schema{Type: "string"}},
+		{
+			Name:        "sort",
+			In:          "query",
+			Description: "逗号分隔,字段前加-表示降序。可用字段: " + strings.Join(query.SortFields(), ", "),
+			Schema:      openapi.Schema{Type: "string"},
+		},
+	}
+
+	for _, field := range query.FilterFields() {
+		params = append(params, openapi.Parameter{
+			Name:        "filter[" + field + "]",
+			In:          "query",
+			Description: "按" + field + "过滤,也可用filter[" + field + ".op]=value(op为eq/gt/gte/lt/lte)",
+			Schema:      openapi.Schema{Type: "string"},
+		})
+	}
+
+	return params
+}
+
+// RegisterOpenAPI 通过router.Walk从router上已注册的路由推导出路径、HTTP方法与路径参数,
+// 结合operationDescriptors补全摘要与响应码,生成OpenAPI 3.0文档并挂载到/openapi.json与/docs。
+// 调用方需先完成UserHandler/AuthHandler的RegisterRoutes,再调用本函数
+func RegisterOpenAPI(router *mux.Router, info Info) *openapi.Document {
+	doc := openapi.NewDocument(info)
+
+	doc.AddSchema("UserRequest", openapi.SchemaFromStruct(UserRequest{}))
+	doc.AddSchema("UserResponse", openapi.SchemaFromStruct(UserResponse{}))
+	doc.AddSchema("UserPage", openapi.SchemaFromStruct(UserPage{}))
+	doc.AddSecurityScheme("bearerAuth", openapi.SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: "JWT"})
+
+	router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		tmpl, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+
+		for _, method := range methods {
+			descriptor, ok := operationDescriptors[method+" "+tmpl]
+			if !ok {
+				continue
+			}
+
+			op := openapi.Operation{
+				Summary:   descriptor.summary,
+				Security:  descriptor.security,
+				Responses: descriptor.responses,
+			}
+			for _, match := range pathParamPattern.FindAllStringSubmatch(tmpl, -1) {
+				op.Parameters = append(op.Parameters, openapi.Parameter{
+					Name: match[1], In: "path", Required: true, Schema: openapi.Schema{Type: "string"},
+				})
+			}
+			if tmpl == "/users" && method == "GET" {
+				op.Parameters = append(op.Parameters, listUsersQueryParams()...)
+			}
+			if descriptor.requestBody != "" {
+				op.RequestBody = &openapi.RequestBody{SchemaRef: descriptor.requestBody, Required: true}
+			}
+
+			doc.AddPath(tmpl, method, op)
+		}
+		return nil
+	})
+
+	openapi.Mount(router, doc)
+	return doc
+}
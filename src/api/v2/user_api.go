@@ -1,22 +1,27 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
-	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
+
+	"github.com/ZZYYG/aicr-test-medium-repo/src/apierr"
+	"github.com/ZZYYG/aicr-test-medium-repo/src/events"
+	"github.com/ZZYYG/aicr-test-medium-repo/src/query"
 )
 
 // UserRequest 表示用户请求
 type UserRequest struct {
-	Username  string            `json:"username"`
-	Email     string            `json:"email"`
-	Password  string            `json:"password,omitempty"`
-	FirstName string            `json:"first_name"`
-	LastName  string            `json:"last_name"`
-	Metadata  map[string]string `json:"metadata,omitempty"`
+	Username  string            `json:"username" validate:"required,alphanum,min=3,max=32"`
+	Email     string            `json:"email" validate:"required,email"`
+	Password  string            `json:"password,omitempty" validate:"omitempty,min=8,max=128"`
+	FirstName string            `json:"first_name" validate:"max=128"`
+	LastName  string            `json:"last_name" validate:"max=128"`
+	Metadata  map[string]string `json:"metadata,omitempty" validate:"omitempty,max=32,dive,keys,max=64,endkeys,max=1024"`
 }
 
 // UserResponse 表示用户响应
@@ -33,7 +38,9 @@ type UserResponse struct {
 
 // UserHandler 处理用户相关的API请求
 type UserHandler struct {
-	service UserService
+	service   UserService
+	auth      *AuthMiddleware
+	publisher events.Publisher
 }
 
 // UserService 定义用户服务接口
@@ -42,39 +49,97 @@ type UserService interface {
 	GetUser(id string) (*UserResponse, error)
 	UpdateUser(id string, req UserRequest) (*UserResponse, error)
 	DeleteUser(id string) error
-	ListUsers(limit, offset int) ([]*UserResponse, int, error)
+	QueryUsers(q query.UserQuery) (UserPage, error)
+}
+
+// PageInfo 描述一页结果相对于完整结果集的位置。只提供NextCursor——向前翻页需要反转排序方向再查询,
+// 目前尚未实现,不要返回一个行为不对的prev_cursor字段
+type PageInfo struct {
+	NextCursor    string `json:"next_cursor,omitempty"`
+	TotalEstimate int    `json:"total_estimate"`
+}
+
+// UserPage 是ListUsers的分页结果
+type UserPage struct {
+	Data []*UserResponse `json:"data"`
+	Page PageInfo        `json:"page"`
 }
 
-// NewUserHandler 创建新的用户处理器
-func NewUserHandler(service UserService) *UserHandler {
+// DefaultUserAuthPolicy 是/users路由默认的鉴权要求:管理员可以创建/列出/删除任意用户,
+// 普通用户只能GET/PUT自己的{id}
+var DefaultUserAuthPolicy = AuthPolicy{
+	Requirements: map[string]RouteRequirement{
+		"POST /users":        {Roles: []string{"admin"}},
+		"GET /users/{id}":    {AllowSelf: true},
+		"PUT /users/{id}":    {AllowSelf: true},
+		"DELETE /users/{id}": {Roles: []string{"admin"}},
+		"GET /users":         {Roles: []string{"admin"}},
+	},
+}
+
+// NewUserHandler 创建新的用户处理器。auth为nil时路由不做认证校验,publisher为nil时跳过事件发布,
+// 两者都可以注入fake以方便测试
+func NewUserHandler(service UserService, auth *AuthMiddleware, publisher events.Publisher) *UserHandler {
 	return &UserHandler{
-		service: service,
+		service:   service,
+		auth:      auth,
+		publisher: publisher,
+	}
+}
+
+// publish在存储层提交之后尽力发布一次生命周期事件;这里的发布与storage包的outbox分发器相互独立——
+// outbox保证了持久化层面的at-least-once投递,这里的直接发布用于不经过GormUserService的部署形态
+func (h *UserHandler) publish(eventType string, resp *UserResponse) {
+	if h.publisher == nil || resp == nil {
+		return
 	}
+	_ = h.publisher.Publish(context.Background(), events.NewCloudEvent(eventType, resp.ID, resp))
 }
 
-// RegisterRoutes 注册路由
+// RegisterRoutes 注册路由,并按AuthPolicy为每个路由套上认证/鉴权中间件
 func (h *UserHandler) RegisterRoutes(router *mux.Router) {
-	router.HandleFunc("/users", h.CreateUser).Methods("POST")
-	router.HandleFunc("/users/{id}", h.GetUser).Methods("GET")
-	router.HandleFunc("/users/{id}", h.UpdateUser).Methods("PUT")
-	router.HandleFunc("/users/{id}", h.DeleteUser).Methods("DELETE")
-	router.HandleFunc("/users", h.ListUsers).Methods("GET")
+	register := func(path, method string, handler http.HandlerFunc) {
+		if h.auth != nil {
+			handler = h.auth.Wrap(method, path, handler)
+		}
+		router.HandleFunc(path, handler).Methods(method)
+	}
+
+	register("/users", "POST", h.CreateUser)
+	register("/users/{id}", "GET", h.GetUser)
+	register("/users/{id}", "PUT", h.UpdateUser)
+	register("/users/{id}", "DELETE", h.DeleteUser)
+	register("/users", "GET", h.ListUsers)
 }
 
 // CreateUser 创建新用户
 func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	var req UserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "无效的请求数据", http.StatusBadRequest)
+		apierr.WriteError(w, apierr.NewValidationError([]apierr.FieldError{{Field: "_", Reason: "请求体不是合法的JSON"}}))
+		return
+	}
+
+	if details := apierr.ValidateStruct(req); details != nil {
+		apierr.WriteError(w, apierr.NewValidationError(details))
+		return
+	}
+
+	// Password上的validator标签是omitempty,因为UserRequest同时服务于更新(允许不改密码)——
+	// 创建时必须显式要求非空密码,否则会静默地用空字符串的bcrypt哈希创建账号
+	if err := apierr.ValidateVar(req.Password, "required,min=8,max=128"); err != nil {
+		apierr.WriteError(w, apierr.NewValidationError([]apierr.FieldError{{Field: "password", Reason: "创建用户时必须提供密码"}}))
 		return
 	}
 
 	resp, err := h.service.CreateUser(req)
 	if err != nil {
-		http.Error(w, "创建用户失败: "+err.Error(), http.StatusInternalServerError)
+		apierr.WriteError(w, err)
 		return
 	}
 
+	h.publish(events.TypeUserCreated, resp)
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(resp)
@@ -87,12 +152,12 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := h.service.GetUser(id)
 	if err != nil {
-		http.Error(w, "获取用户失败: "+err.Error(), http.StatusInternalServerError)
+		apierr.WriteError(w, err)
 		return
 	}
 
 	if resp == nil {
-		http.Error(w, "用户不存在", http.StatusNotFound)
+		apierr.WriteError(w, apierr.ErrNotFound)
 		return
 	}
 
@@ -107,21 +172,28 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 
 	var req UserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "无效的请求数据", http.StatusBadRequest)
+		apierr.WriteError(w, apierr.NewValidationError([]apierr.FieldError{{Field: "_", Reason: "请求体不是合法的JSON"}}))
+		return
+	}
+
+	if details := apierr.ValidateStruct(req); details != nil {
+		apierr.WriteError(w, apierr.NewValidationError(details))
 		return
 	}
 
 	resp, err := h.service.UpdateUser(id, req)
 	if err != nil {
-		http.Error(w, "更新用户失败: "+err.Error(), http.StatusInternalServerError)
+		apierr.WriteError(w, err)
 		return
 	}
 
 	if resp == nil {
-		http.Error(w, "用户不存在", http.StatusNotFound)
+		apierr.WriteError(w, apierr.ErrNotFound)
 		return
 	}
 
+	h.publish(events.TypeUserUpdated, resp)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
@@ -132,44 +204,51 @@ func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	id := vars["id"]
 
 	if err := h.service.DeleteUser(id); err != nil {
-		http.Error(w, "删除用户失败: "+err.Error(), http.StatusInternalServerError)
+		apierr.WriteError(w, err)
 		return
 	}
 
+	h.publish(events.TypeUserDeleted, &UserResponse{ID: id})
+
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// ListUsers 列出所有用户
+// ListUsers 列出用户,支持游标分页、多字段排序、按字段过滤与全文检索
 func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
-	limit := 10
-	offset := 0
+	params := r.URL.Query()
+	builder := query.NewBuilder()
 
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if val, err := strconv.Atoi(limitStr); err == nil && val > 0 {
-			limit = val
-		}
+	if err := builder.WithCursor(params.Get("cursor")); err != nil {
+		apierr.WriteError(w, apierr.NewValidationError([]apierr.FieldError{{Field: "cursor", Reason: "无效的分页游标"}}))
+		return
+	}
+	if err := builder.WithLimit(params.Get("limit")); err != nil {
+		apierr.WriteError(w, apierr.NewValidationError([]apierr.FieldError{{Field: "limit", Reason: err.Error()}}))
+		return
 	}
+	if err := builder.WithSort(params.Get("sort")); err != nil {
+		apierr.WriteError(w, apierr.NewValidationError([]apierr.FieldError{{Field: "sort", Reason: err.Error()}}))
+		return
+	}
+	builder.WithSearch(params.Get("q"))
 
-	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
-		if val, err := strconv.Atoi(offsetStr); err == nil && val >= 0 {
-			offset = val
+	for key, values := range params {
+		if !strings.HasPrefix(key, "filter[") || !strings.HasSuffix(key, "]") {
+			continue
+		}
+		field := strings.TrimSuffix(strings.TrimPrefix(key, "filter["), "]")
+		if err := builder.WithFilter(field, values[0]); err != nil {
+			apierr.WriteError(w, apierr.NewValidationError([]apierr.FieldError{{Field: field, Reason: err.Error()}}))
+			return
 		}
 	}
 
-	users, total, err := h.service.ListUsers(limit, offset)
+	page, err := h.service.QueryUsers(builder.Build())
 	if err != nil {
-		http.Error(w, "获取用户列表失败: "+err.Error(), http.StatusInternalServerError)
+		apierr.WriteError(w, err)
 		return
 	}
 
-	response := struct {
-		Total int             `json:"total"`
-		Users []*UserResponse `json:"users"`
-	}{
-		Total: total,
-		Users: users,
-	}
-
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	json.NewEncoder(w).Encode(page)
 }
@@ -0,0 +1,75 @@
+package apierr
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// FieldError 描述请求中某个字段未通过校验的原因
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// Error 是所有API错误响应共用的结构,状态码由status决定,message面向客户端展示
+type Error struct {
+	Code      string       `json:"code"`
+	Message   string       `json:"message"`
+	Details   []FieldError `json:"details,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	TraceID   string       `json:"trace_id,omitempty"`
+
+	status int
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func newError(status int, code, message string) *Error {
+	return &Error{Code: code, Message: message, status: status}
+}
+
+// 预置的哨兵错误,按语义覆盖最常见的失败场景;使用WithDetails/WithRequestID派生携带上下文的副本
+var (
+	ErrNotFound     = newError(http.StatusNotFound, "not_found", "资源不存在")
+	ErrConflict     = newError(http.StatusConflict, "conflict", "资源冲突")
+	ErrValidation   = newError(http.StatusBadRequest, "validation_error", "请求参数校验失败")
+	ErrUnauthorized = newError(http.StatusUnauthorized, "unauthorized", "未认证或认证已失效")
+	ErrForbidden    = newError(http.StatusForbidden, "forbidden", "没有权限执行该操作")
+	ErrInternal     = newError(http.StatusInternalServerError, "internal_error", "服务器内部错误")
+)
+
+// WithDetails 返回携带字段级别详情的副本
+func (e *Error) WithDetails(details []FieldError) *Error {
+	clone := *e
+	clone.Details = details
+	return &clone
+}
+
+// WithRequestID 返回携带request_id的副本,便于客户端在工单中引用
+func (e *Error) WithRequestID(id string) *Error {
+	clone := *e
+	clone.RequestID = id
+	return &clone
+}
+
+// NewValidationError 基于字段校验详情构造一个ErrValidation的副本
+func NewValidationError(details []FieldError) *Error {
+	return ErrValidation.WithDetails(details)
+}
+
+type envelope struct {
+	Error *Error `json:"error"`
+}
+
+// WriteError 将err写成统一的JSON错误信封。非*Error类型一律映射为ErrInternal,避免向客户端泄漏内部错误文本
+func WriteError(w http.ResponseWriter, err error) {
+	apiErr, ok := err.(*Error)
+	if !ok {
+		apiErr = ErrInternal
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(apiErr.status)
+	json.NewEncoder(w).Encode(envelope{Error: apiErr})
+}
@@ -0,0 +1,46 @@
+package apierr
+
+import (
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	zhtranslations "github.com/go-playground/validator/v10/translations/zh"
+)
+
+var (
+	validate   = validator.New()
+	translator ut.Translator
+)
+
+func init() {
+	uni := ut.New(en.New(), zh.New())
+	translator, _ = uni.GetTranslator("zh")
+	_ = zhtranslations.RegisterDefaultTranslations(validate, translator)
+}
+
+// ValidateStruct 校验s上的validator标签,返回逐字段的错误详情;全部通过时返回nil
+func ValidateStruct(s interface{}) []FieldError {
+	err := validate.Struct(s)
+	if err == nil {
+		return nil
+	}
+
+	verrs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return []FieldError{{Field: "_", Reason: err.Error()}}
+	}
+
+	details := make([]FieldError, 0, len(verrs))
+	for _, fe := range verrs {
+		details = append(details, FieldError{Field: fe.Field(), Reason: fe.Translate(translator)})
+	}
+	return details
+}
+
+// ValidateVar 按validator标签语法(如"required,min=8,max=128")校验单个值,用于请求体的
+// omitempty字段在某些场景下需要额外约束的情况(例如UserRequest被创建与更新复用,
+// 密码只在创建时必填,无法靠struct标签本身表达)
+func ValidateVar(value interface{}, tag string) error {
+	return validate.Var(value, tag)
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// DatabaseConfig 描述连接数据库所需的参数
+type DatabaseConfig struct {
+	Driver   string `mapstructure:"driver"`
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	User     string `mapstructure:"user"`
+	Password string `mapstructure:"password"`
+	Name     string `mapstructure:"name"`
+}
+
+// Config 是应用的根配置
+type Config struct {
+	Database DatabaseConfig `mapstructure:"database"`
+}
+
+// Loader 从YAML文件加载Config,并在文件变化时触发回调,便于上层重建依赖该配置的组件
+type Loader struct {
+	v *viper.Viper
+
+	mu        sync.RWMutex
+	current   *Config
+	listeners []func(Config)
+}
+
+// NewLoader 读取path指定的YAML配置文件并开始监听其变化
+func NewLoader(path string) (*Loader, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	l := &Loader{v: v}
+	if err := l.reload(); err != nil {
+		return nil, err
+	}
+
+	v.OnConfigChange(func(_ fsnotify.Event) {
+		if err := l.reload(); err == nil {
+			l.notify()
+		}
+	})
+	v.WatchConfig()
+
+	return l, nil
+}
+
+func (l *Loader) reload() error {
+	var cfg Config
+	if err := l.v.Unmarshal(&cfg); err != nil {
+		return fmt.Errorf("解析配置文件失败: %w", err)
+	}
+	l.mu.Lock()
+	l.current = &cfg
+	l.mu.Unlock()
+	return nil
+}
+
+func (l *Loader) notify() {
+	cfg := l.Current()
+	l.mu.RLock()
+	listeners := append([]func(Config){}, l.listeners...)
+	l.mu.RUnlock()
+	for _, fn := range listeners {
+		fn(cfg)
+	}
+}
+
+// Current 返回最近一次加载成功的配置快照
+func (l *Loader) Current() Config {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return *l.current
+}
+
+// OnChange 注册一个回调,在配置热重载成功后以最新配置调用
+func (l *Loader) OnChange(fn func(Config)) {
+	l.mu.Lock()
+	l.listeners = append(l.listeners, fn)
+	l.mu.Unlock()
+}
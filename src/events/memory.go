@@ -0,0 +1,27 @@
+package events
+
+import "context"
+
+// InMemoryPublisher 把事件写入一个内存channel,用于单元测试与本地开发,不做持久化或重试
+type InMemoryPublisher struct {
+	events chan CloudEvent
+}
+
+// NewInMemoryPublisher 创建一个带缓冲channel的内存发布器
+func NewInMemoryPublisher(buffer int) *InMemoryPublisher {
+	return &InMemoryPublisher{events: make(chan CloudEvent, buffer)}
+}
+
+func (p *InMemoryPublisher) Publish(ctx context.Context, event CloudEvent) error {
+	select {
+	case p.events <- event:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Events 暴露只读channel供测试断言发布的事件
+func (p *InMemoryPublisher) Events() <-chan CloudEvent {
+	return p.events
+}
@@ -0,0 +1,50 @@
+package events
+
+import (
+	"context"
+	"time"
+)
+
+// Event types published for the user lifecycle
+const (
+	TypeUserCreated = "user.created"
+	TypeUserUpdated = "user.updated"
+	TypeUserDeleted = "user.deleted"
+)
+
+// Source is the CloudEvents "source" attribute for every event emitted by this service
+const Source = "/aicr-test-medium-repo/users"
+
+// CloudEvent 是符合CloudEvents 1.0规范的事件信封
+type CloudEvent struct {
+	SpecVersion     string      `json:"specversion"`
+	ID              string      `json:"id"`
+	Source          string      `json:"source"`
+	Type            string      `json:"type"`
+	Time            time.Time   `json:"time"`
+	Subject         string      `json:"subject"`
+	DataContentType string      `json:"datacontenttype"`
+	Data            interface{} `json:"data"`
+}
+
+// NewCloudEvent 构造一个符合CloudEvents 1.0规范的事件,id由事件类型、subject与生成时刻拼接而成
+func NewCloudEvent(eventType, subject string, data interface{}) CloudEvent {
+	return CloudEvent{
+		SpecVersion:     "1.0",
+		ID:              eventType + ":" + subject + ":" + timeNow().Format(time.RFC3339Nano),
+		Source:          Source,
+		Type:            eventType,
+		Time:            timeNow(),
+		Subject:         subject,
+		DataContentType: "application/json",
+		Data:            data,
+	}
+}
+
+// timeNow存在是为了将来在测试中可以替换时钟,目前直接委托给time.Now
+var timeNow = time.Now
+
+// Publisher 把CloudEvent发布到某个消息中间件,实现需保证Publish在broker不可达时返回可重试的error
+type Publisher interface {
+	Publish(ctx context.Context, event CloudEvent) error
+}
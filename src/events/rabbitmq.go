@@ -0,0 +1,36 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// RabbitMQPublisher 把事件发布到一个RabbitMQ topic exchange,routing key使用事件类型(如"user.created")
+type RabbitMQPublisher struct {
+	channel  *amqp.Channel
+	exchange string
+}
+
+// NewRabbitMQPublisher 在channel上声明一个durable topic exchange并返回对应的Publisher
+func NewRabbitMQPublisher(channel *amqp.Channel, exchange string) (*RabbitMQPublisher, error) {
+	err := channel.ExchangeDeclare(exchange, "topic", true, false, false, false, nil)
+	if err != nil {
+		return nil, fmt.Errorf("声明exchange失败: %w", err)
+	}
+	return &RabbitMQPublisher{channel: channel, exchange: exchange}, nil
+}
+
+func (p *RabbitMQPublisher) Publish(ctx context.Context, event CloudEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return p.channel.PublishWithContext(ctx, p.exchange, event.Type, false, false, amqp.Publishing{
+		ContentType: "application/cloudevents+json",
+		Body:        body,
+	})
+}
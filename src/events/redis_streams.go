@@ -0,0 +1,37 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStreamsPublisher 把事件追加到一个Redis Stream,流名默认为"user.events"
+type RedisStreamsPublisher struct {
+	client *redis.Client
+	stream string
+}
+
+// NewRedisStreamsPublisher 创建基于Redis Streams的发布器
+func NewRedisStreamsPublisher(client *redis.Client, stream string) *RedisStreamsPublisher {
+	if stream == "" {
+		stream = "user.events"
+	}
+	return &RedisStreamsPublisher{client: client, stream: stream}
+}
+
+func (p *RedisStreamsPublisher) Publish(ctx context.Context, event CloudEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return p.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: p.stream,
+		Values: map[string]interface{}{
+			"type": event.Type,
+			"data": body,
+		},
+	}).Err()
+}
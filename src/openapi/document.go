@@ -0,0 +1,192 @@
+package openapi
+
+import (
+	"sort"
+	"strings"
+)
+
+// Info 是OpenAPI文档的顶层元信息
+type Info struct {
+	Title       string
+	Version     string
+	Description string
+}
+
+// Schema 是OpenAPI Schema Object的一个精简子集,足以描述本仓库使用的JSON结构
+type Schema struct {
+	Type       string            `json:"type,omitempty"`
+	Format     string            `json:"format,omitempty"`
+	Ref        string            `json:"$ref,omitempty"`
+	Properties map[string]Schema `json:"properties,omitempty"`
+	Items      *Schema           `json:"items,omitempty"`
+	Required   []string          `json:"required,omitempty"`
+}
+
+// Parameter 描述一个路径或查询参数
+type Parameter struct {
+	Name        string `json:"name"`
+	In          string `json:"in"`
+	Required    bool   `json:"required"`
+	Description string `json:"description,omitempty"`
+	Schema      Schema `json:"schema"`
+}
+
+// RequestBody 描述请求体,SchemaRef指向Document已注册的schema名称
+type RequestBody struct {
+	SchemaRef string
+	Required  bool
+}
+
+// Response 描述一个响应状态码,SchemaRef为空表示无响应体
+type Response struct {
+	Description string
+	SchemaRef   string
+}
+
+// Operation 描述路径下某个HTTP方法的行为
+type Operation struct {
+	Summary     string
+	Parameters  []Parameter
+	RequestBody *RequestBody
+	Responses   map[string]Response
+	Security    []string
+}
+
+// SecurityScheme 描述一种认证方式,如Bearer JWT
+type SecurityScheme struct {
+	Type         string
+	Scheme       string
+	BearerFormat string
+}
+
+// Document 是逐步拼装的OpenAPI 3.0文档,供各Handler在注册路由时追加自己的路径与schema
+type Document struct {
+	info            Info
+	paths           map[string]map[string]Operation
+	schemas         map[string]Schema
+	securitySchemes map[string]SecurityScheme
+}
+
+// NewDocument 创建一个空文档
+func NewDocument(info Info) *Document {
+	return &Document{
+		info:            info,
+		paths:           make(map[string]map[string]Operation),
+		schemas:         make(map[string]Schema),
+		securitySchemes: make(map[string]SecurityScheme),
+	}
+}
+
+// AddPath 为path上的method注册一个Operation,method使用大写HTTP方法名
+func (d *Document) AddPath(path, method string, op Operation) {
+	if d.paths[path] == nil {
+		d.paths[path] = make(map[string]Operation)
+	}
+	d.paths[path][method] = op
+}
+
+// AddSchema 注册一个可被$ref引用的schema
+func (d *Document) AddSchema(name string, schema Schema) {
+	d.schemas[name] = schema
+}
+
+// AddSecurityScheme 注册一种认证方式
+func (d *Document) AddSecurityScheme(name string, scheme SecurityScheme) {
+	d.securitySchemes[name] = scheme
+}
+
+// Build 将累积的路径、schema与安全方案渲染为可直接json.Marshal的OpenAPI 3.0文档
+func (d *Document) Build() map[string]interface{} {
+	paths := make(map[string]interface{}, len(d.paths))
+	for path, methods := range d.paths {
+		methodMap := make(map[string]interface{}, len(methods))
+		for method, op := range methods {
+			methodMap[lowerMethod(method)] = renderOperation(op)
+		}
+		paths[path] = methodMap
+	}
+
+	schemas := make(map[string]interface{}, len(d.schemas))
+	for name, schema := range d.schemas {
+		schemas[name] = schema
+	}
+
+	securitySchemes := make(map[string]interface{}, len(d.securitySchemes))
+	for name, scheme := range d.securitySchemes {
+		entry := map[string]interface{}{"type": scheme.Type}
+		if scheme.Scheme != "" {
+			entry["scheme"] = scheme.Scheme
+		}
+		if scheme.BearerFormat != "" {
+			entry["bearerFormat"] = scheme.BearerFormat
+		}
+		securitySchemes[name] = entry
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       d.info.Title,
+			"version":     d.info.Version,
+			"description": d.info.Description,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas":         schemas,
+			"securitySchemes": securitySchemes,
+		},
+	}
+}
+
+func renderOperation(op Operation) map[string]interface{} {
+	rendered := map[string]interface{}{"summary": op.Summary}
+
+	if len(op.Parameters) > 0 {
+		rendered["parameters"] = op.Parameters
+	}
+
+	if op.RequestBody != nil {
+		rendered["requestBody"] = map[string]interface{}{
+			"required": op.RequestBody.Required,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": map[string]interface{}{"$ref": "#/components/schemas/" + op.RequestBody.SchemaRef},
+				},
+			},
+		}
+	}
+
+	responses := make(map[string]interface{}, len(op.Responses))
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		resp := op.Responses[code]
+		entry := map[string]interface{}{"description": resp.Description}
+		if resp.SchemaRef != "" {
+			entry["content"] = map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": map[string]interface{}{"$ref": "#/components/schemas/" + resp.SchemaRef},
+				},
+			}
+		}
+		responses[code] = entry
+	}
+	rendered["responses"] = responses
+
+	if len(op.Security) > 0 {
+		security := make([]map[string][]string, 0, len(op.Security))
+		for _, name := range op.Security {
+			security = append(security, map[string][]string{name: {}})
+		}
+		rendered["security"] = security
+	}
+
+	return rendered
+}
+
+func lowerMethod(method string) string {
+	return strings.ToLower(method)
+}
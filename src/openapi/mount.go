@@ -0,0 +1,28 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// docsPage是一个完全离线渲染/openapi.json的静态文档页面,通过go:embed编译进二进制,
+// 不依赖CDN加载的swagger-ui-dist,使/docs在无法访问外网的部署环境下也能正常使用
+//
+//go:embed assets/docs.html
+var docsPage []byte
+
+// Mount 在路由上挂载/openapi.json(生成的文档)与/docs(内嵌的静态文档页面)
+func Mount(router *mux.Router, doc *Document) {
+	router.HandleFunc("/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(doc.Build())
+	}).Methods("GET")
+
+	router.HandleFunc("/docs", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(docsPage)
+	}).Methods("GET")
+}
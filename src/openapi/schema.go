@@ -0,0 +1,88 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+)
+
+// SchemaFromStruct 通过反射从结构体的json标签推导出一个对象Schema,
+// 足以覆盖本仓库请求/响应结构体这类平坦的DTO,不处理匿名嵌入或递归类型
+func SchemaFromStruct(v interface{}) Schema {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return Schema{Type: goKindToSchemaType(t.Kind())}
+	}
+
+	properties := make(map[string]Schema)
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		jsonTag := field.Tag.Get("json")
+		name := field.Name
+		omitempty := false
+		if jsonTag != "" {
+			parts := strings.Split(jsonTag, ",")
+			if parts[0] == "-" {
+				continue
+			}
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			for _, opt := range parts[1:] {
+				if opt == "omitempty" {
+					omitempty = true
+				}
+			}
+		}
+
+		properties[name] = schemaForType(field.Type)
+		if !omitempty {
+			required = append(required, name)
+		}
+	}
+
+	return Schema{Type: "object", Properties: properties, Required: required}
+}
+
+func schemaForType(t reflect.Type) Schema {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaForType(t.Elem())
+	case reflect.Slice, reflect.Array:
+		item := schemaForType(t.Elem())
+		return Schema{Type: "array", Items: &item}
+	case reflect.Map:
+		return Schema{Type: "object"}
+	case reflect.Struct:
+		if t.PkgPath() == "time" && t.Name() == "Time" {
+			return Schema{Type: "string", Format: "date-time"}
+		}
+		return SchemaFromStruct(reflect.New(t).Elem().Interface())
+	default:
+		return Schema{Type: goKindToSchemaType(t.Kind())}
+	}
+}
+
+func goKindToSchemaType(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	default:
+		return "object"
+	}
+}
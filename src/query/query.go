@@ -0,0 +1,180 @@
+package query
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor 表示游标无法被解码,通常意味着客户端传入了损坏或伪造的值
+var ErrInvalidCursor = errors.New("无效的分页游标")
+
+// Cursor 是不透明分页游标解码后的内容,基于(last_id, last_created_at)以保证插入下的稳定性
+type Cursor struct {
+	LastID        string    `json:"last_id"`
+	LastCreatedAt time.Time `json:"last_created_at"`
+}
+
+// EncodeCursor 将Cursor编码为URL安全的base64字符串
+func EncodeCursor(c Cursor) (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor 解析客户端传入的游标,格式不合法时返回ErrInvalidCursor
+func DecodeCursor(raw string) (Cursor, error) {
+	var c Cursor
+	data, err := base64.URLEncoding.DecodeString(raw)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// SortField 描述一个排序字段及方向,Desc对应查询串中的"-field"前缀
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// FilterCondition 描述一个字段上的过滤条件,例如 filter[created_at.gte]=2024-01-01
+type FilterCondition struct {
+	Field string
+	Op    string
+	Value string
+}
+
+const (
+	// DefaultLimit 是未指定limit时使用的页大小
+	DefaultLimit = 20
+	// MaxLimit 是limit参数允许的最大值,超出时静默截断以避免全表扫描式请求
+	MaxLimit = 200
+)
+
+var allowedSortFields = map[string]bool{"id": true, "created_at": true, "username": true, "email": true}
+var allowedFilterFields = map[string]bool{"email": true, "username": true, "created_at": true}
+var allowedFilterOps = map[string]bool{"eq": true, "gte": true, "lte": true, "gt": true, "lt": true}
+
+// SortFields 返回?sort=接受的字段名,供文档生成等场景复用,避免维护第二份白名单
+func SortFields() []string {
+	return sortedKeys(allowedSortFields)
+}
+
+// FilterFields 返回?filter[...]接受的字段名,供文档生成等场景复用,避免维护第二份白名单
+func FilterFields() []string {
+	return sortedKeys(allowedFilterFields)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// UserQuery 是构建ListUsers查询所需的全部参数
+type UserQuery struct {
+	Cursor  *Cursor
+	Limit   int
+	Sort    []SortField
+	Filters []FilterCondition
+	Search  string
+}
+
+// Builder 将HTTP查询参数逐步解析为UserQuery,并在遇到非法输入时返回错误供handler转为400响应
+type Builder struct {
+	query UserQuery
+}
+
+// NewBuilder 创建一个使用默认分页大小的构建器
+func NewBuilder() *Builder {
+	return &Builder{query: UserQuery{Limit: DefaultLimit}}
+}
+
+// WithCursor 解析?cursor=参数
+func (b *Builder) WithCursor(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	c, err := DecodeCursor(raw)
+	if err != nil {
+		return err
+	}
+	b.query.Cursor = &c
+	return nil
+}
+
+// WithLimit 解析?limit=参数,超过MaxLimit时截断
+func (b *Builder) WithLimit(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		return fmt.Errorf("无效的limit参数: %s", raw)
+	}
+	if n > MaxLimit {
+		n = MaxLimit
+	}
+	b.query.Limit = n
+	return nil
+}
+
+// WithSort 解析?sort=field[,-field]参数
+func (b *Builder) WithSort(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		desc := strings.HasPrefix(part, "-")
+		field := strings.TrimPrefix(part, "-")
+		if !allowedSortFields[field] {
+			return fmt.Errorf("不支持的排序字段: %s", field)
+		}
+		b.query.Sort = append(b.query.Sort, SortField{Field: field, Desc: desc})
+	}
+	return nil
+}
+
+// WithFilter 解析单个?filter[field]=value或?filter[field.op]=value参数
+func (b *Builder) WithFilter(key, value string) error {
+	field, op, ok := strings.Cut(key, ".")
+	if !ok {
+		op = "eq"
+	}
+	if !allowedFilterFields[field] {
+		return fmt.Errorf("不支持的过滤字段: %s", field)
+	}
+	if !allowedFilterOps[op] {
+		return fmt.Errorf("不支持的过滤操作符: %s", op)
+	}
+	b.query.Filters = append(b.query.Filters, FilterCondition{Field: field, Op: op, Value: value})
+	return nil
+}
+
+// WithSearch 设置?q=全文检索关键字
+func (b *Builder) WithSearch(q string) {
+	b.query.Search = q
+}
+
+// Build 返回构建完成的UserQuery
+func (b *Builder) Build() UserQuery {
+	return b.query
+}
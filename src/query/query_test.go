@@ -0,0 +1,91 @@
+package query
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	original := Cursor{LastID: "42", LastCreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+	encoded, err := EncodeCursor(original)
+	if err != nil {
+		t.Fatalf("编码游标失败: %v", err)
+	}
+
+	decoded, err := DecodeCursor(encoded)
+	if err != nil {
+		t.Fatalf("解码游标失败: %v", err)
+	}
+
+	if decoded.LastID != original.LastID || !decoded.LastCreatedAt.Equal(original.LastCreatedAt) {
+		t.Fatalf("解码结果与原始游标不一致: got %+v, want %+v", decoded, original)
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	if _, err := DecodeCursor("not-valid-base64!!"); err != ErrInvalidCursor {
+		t.Fatalf("非法base64应当返回ErrInvalidCursor, got %v", err)
+	}
+	if _, err := DecodeCursor("bm90LWpzb24="); err != ErrInvalidCursor {
+		t.Fatalf("合法base64但非法JSON结构应当返回ErrInvalidCursor, got %v", err)
+	}
+}
+
+func TestBuilderWithCursor(t *testing.T) {
+	cursor := Cursor{LastID: "7", LastCreatedAt: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)}
+	encoded, err := EncodeCursor(cursor)
+	if err != nil {
+		t.Fatalf("编码游标失败: %v", err)
+	}
+
+	b := NewBuilder()
+	if err := b.WithCursor(encoded); err != nil {
+		t.Fatalf("WithCursor不应报错: %v", err)
+	}
+
+	got := b.Build()
+	if got.Cursor == nil || got.Cursor.LastID != cursor.LastID || !got.Cursor.LastCreatedAt.Equal(cursor.LastCreatedAt) {
+		t.Fatalf("Builder解析出的游标与原始值不一致: got %+v, want %+v", got.Cursor, cursor)
+	}
+}
+
+func TestBuilderWithSortRejectsUnknownField(t *testing.T) {
+	b := NewBuilder()
+	if err := b.WithSort("bogus_field"); err == nil {
+		t.Fatal("对不在白名单内的排序字段应当返回错误")
+	}
+}
+
+func TestBuilderWithFilterRejectsUnknownOp(t *testing.T) {
+	b := NewBuilder()
+	if err := b.WithFilter("email.bogus_op", "a@example.com"); err == nil {
+		t.Fatal("对不在白名单内的过滤操作符应当返回错误")
+	}
+}
+
+func TestBuilderWithLimitClampsToMax(t *testing.T) {
+	b := NewBuilder()
+	if err := b.WithLimit("100000"); err != nil {
+		t.Fatalf("WithLimit不应报错: %v", err)
+	}
+	if got := b.Build().Limit; got != MaxLimit {
+		t.Fatalf("超出MaxLimit的limit应当被截断为%d, got %d", MaxLimit, got)
+	}
+}
+
+func TestSortAndFilterFieldsAreSorted(t *testing.T) {
+	fields := SortFields()
+	for i := 1; i < len(fields); i++ {
+		if fields[i-1] > fields[i] {
+			t.Fatalf("SortFields应当按字典序返回,got %v", fields)
+		}
+	}
+
+	filters := FilterFields()
+	for i := 1; i < len(filters); i++ {
+		if filters[i-1] > filters[i] {
+			t.Fatalf("FilterFields应当按字典序返回,got %v", filters)
+		}
+	}
+}
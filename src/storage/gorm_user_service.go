@@ -0,0 +1,268 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	api "github.com/ZZYYG/aicr-test-medium-repo/src/api/v2"
+	"github.com/ZZYYG/aicr-test-medium-repo/src/apierr"
+	"github.com/ZZYYG/aicr-test-medium-repo/src/events"
+	"github.com/ZZYYG/aicr-test-medium-repo/src/query"
+)
+
+// GormUserService 是UserService基于GORM的实现,支持MySQL/Postgres/SQLite
+type GormUserService struct {
+	db *gorm.DB
+}
+
+// NewGormUserService 创建GormUserService并执行自动迁移。Create/Update/Delete在写入User的同一事务内
+// 追加一行OutboxEvent,真正的投递交给OutboxDispatcher异步完成
+func NewGormUserService(db *gorm.DB) (*GormUserService, error) {
+	if err := db.AutoMigrate(&User{}, &UserMetadata{}, &OutboxEvent{}); err != nil {
+		return nil, err
+	}
+	return &GormUserService{db: db}, nil
+}
+
+func toResponse(u *User) *api.UserResponse {
+	metadata := make(map[string]string, len(u.Metadata))
+	for _, m := range u.Metadata {
+		metadata[m.Key] = m.Value
+	}
+	return &api.UserResponse{
+		ID:        toExternalID(u.ID),
+		Username:  u.Username,
+		Email:     u.Email,
+		FirstName: u.FirstName,
+		LastName:  u.LastName,
+		Metadata:  metadata,
+		CreatedAt: u.CreatedAt,
+		UpdatedAt: u.UpdatedAt,
+	}
+}
+
+func metadataRows(userID uint, metadata map[string]string) []UserMetadata {
+	rows := make([]UserMetadata, 0, len(metadata))
+	for k, v := range metadata {
+		rows = append(rows, UserMetadata{UserID: userID, Key: k, Value: v})
+	}
+	return rows
+}
+
+func (s *GormUserService) CreateUser(req api.UserRequest) (*api.UserResponse, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := User{
+		Username:     req.Username,
+		Email:        req.Email,
+		PasswordHash: string(hash),
+		FirstName:    req.FirstName,
+		LastName:     req.LastName,
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		if len(req.Metadata) > 0 {
+			if err := tx.Create(metadataRows(user.ID, req.Metadata)).Error; err != nil {
+				return err
+			}
+		}
+
+		resp := toResponse(&user)
+		return enqueueOutboxEvent(tx, events.NewCloudEvent(events.TypeUserCreated, resp.ID, resp))
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, apierr.ErrConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user.Metadata = metadataRows(user.ID, req.Metadata)
+	return toResponse(&user), nil
+}
+
+func (s *GormUserService) GetUser(id string) (*api.UserResponse, error) {
+	internalID, err := fromExternalID(id)
+	if err != nil {
+		return nil, nil
+	}
+
+	var user User
+	err = s.db.Preload("Metadata").First(&user, internalID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toResponse(&user), nil
+}
+
+func (s *GormUserService) UpdateUser(id string, req api.UserRequest) (*api.UserResponse, error) {
+	internalID, err := fromExternalID(id)
+	if err != nil {
+		return nil, nil
+	}
+
+	var user User
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&user, internalID).Error; err != nil {
+			return err
+		}
+
+		user.Username = req.Username
+		user.Email = req.Email
+		user.FirstName = req.FirstName
+		user.LastName = req.LastName
+		if req.Password != "" {
+			hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+			if err != nil {
+				return err
+			}
+			user.PasswordHash = string(hash)
+		}
+
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", user.ID).Delete(&UserMetadata{}).Error; err != nil {
+			return err
+		}
+		if len(req.Metadata) > 0 {
+			if err := tx.Create(metadataRows(user.ID, req.Metadata)).Error; err != nil {
+				return err
+			}
+		}
+
+		resp := toResponse(&user)
+		return enqueueOutboxEvent(tx, events.NewCloudEvent(events.TypeUserUpdated, resp.ID, resp))
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, apierr.ErrConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user.Metadata = metadataRows(user.ID, req.Metadata)
+	return toResponse(&user), nil
+}
+
+func (s *GormUserService) DeleteUser(id string) error {
+	internalID, err := fromExternalID(id)
+	if err != nil {
+		return apierr.ErrNotFound
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&User{}, internalID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return apierr.ErrNotFound
+		}
+		return enqueueOutboxEvent(tx, events.NewCloudEvent(events.TypeUserDeleted, id, nil))
+	})
+}
+
+// Ping 实现api.Pinger,供/readyz探测底层数据库连接是否可用
+func (s *GormUserService) Ping(ctx context.Context) error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+var filterOperators = map[string]string{
+	"eq":  "=",
+	"gte": ">=",
+	"lte": "<=",
+	"gt":  ">",
+	"lt":  "<",
+}
+
+// QueryUsers 实现api.UserService的游标分页、排序、过滤与全文检索查询
+func (s *GormUserService) QueryUsers(q query.UserQuery) (api.UserPage, error) {
+	db := s.db.Model(&User{})
+
+	for _, f := range q.Filters {
+		operator, ok := filterOperators[f.Op]
+		if !ok {
+			return api.UserPage{}, fmt.Errorf("不支持的过滤操作符: %s", f.Op)
+		}
+		db = db.Where(fmt.Sprintf("%s %s ?", f.Field, operator), f.Value)
+	}
+
+	if q.Search != "" {
+		like := "%" + q.Search + "%"
+		db = db.Where("username LIKE ? OR email LIKE ?", like, like)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return api.UserPage{}, err
+	}
+
+	if q.Cursor != nil {
+		db = db.Where("(created_at, id) > (?, ?)", q.Cursor.LastCreatedAt, q.Cursor.LastID)
+	}
+
+	if len(q.Sort) == 0 {
+		db = db.Order("created_at").Order("id")
+	}
+	for _, s := range q.Sort {
+		if s.Desc {
+			db = db.Order(s.Field + " DESC")
+		} else {
+			db = db.Order(s.Field)
+		}
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = query.DefaultLimit
+	}
+
+	var users []User
+	if err := db.Preload("Metadata").Limit(limit + 1).Find(&users).Error; err != nil {
+		return api.UserPage{}, err
+	}
+
+	hasMore := len(users) > limit
+	if hasMore {
+		users = users[:limit]
+	}
+
+	responses := make([]*api.UserResponse, 0, len(users))
+	for i := range users {
+		responses = append(responses, toResponse(&users[i]))
+	}
+
+	page := api.UserPage{Data: responses, Page: api.PageInfo{TotalEstimate: int(total)}}
+	if hasMore && len(users) > 0 {
+		last := users[len(users)-1]
+		cursor, err := query.EncodeCursor(query.Cursor{LastID: toExternalID(last.ID), LastCreatedAt: last.CreatedAt})
+		if err != nil {
+			return api.UserPage{}, err
+		}
+		page.Page.NextCursor = cursor
+	}
+
+	return page, nil
+}
@@ -0,0 +1,17 @@
+package storage
+
+import "strconv"
+
+// toExternalID 将数据库自增主键转换为对外暴露的字符串ID
+func toExternalID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// fromExternalID 将对外暴露的字符串ID还原为数据库主键,格式不合法时返回错误
+func fromExternalID(id string) (uint, error) {
+	parsed, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return uint(parsed), nil
+}
@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"time"
+)
+
+// User 是用户在数据库中的持久化模型。刻意不嵌入gorm.Model——它的DeletedAt字段会让username/email上的
+// uniqueIndex连带覆盖已软删除的行,导致删除一个用户后永远无法用同一个用户名/邮箱重新注册,而GORM
+// 没有跨MySQL/Postgres/SQLite统一的局部唯一索引机制可以把deleted_at IS NULL排除在索引之外。
+// 因此DeleteUser做的是真正的物理删除,审计轨迹由enqueueOutboxEvent写入的user.deleted事件承担
+type User struct {
+	ID           uint   `gorm:"primarykey"`
+	Username     string `gorm:"size:64;uniqueIndex;not null"`
+	Email        string `gorm:"size:255;uniqueIndex;not null"`
+	PasswordHash string `gorm:"size:255;not null"`
+	FirstName    string `gorm:"size:128"`
+	LastName     string `gorm:"size:128"`
+	Metadata     []UserMetadata
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// UserMetadata 是User.Metadata map展开后的键值表,一行对应一个键
+type UserMetadata struct {
+	ID        uint   `gorm:"primarykey"`
+	UserID    uint   `gorm:"index;not null"`
+	Key       string `gorm:"size:128;not null"`
+	Value     string `gorm:"size:1024"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TableName 显式指定user_metadata表名,避免GORM按复数规则推导出歧义名称
+func (UserMetadata) TableName() string {
+	return "user_metadata"
+}
@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ZZYYG/aicr-test-medium-repo/src/events"
+)
+
+// OutboxEvent 是落库的待发布事件,与触发它的数据库变更写在同一事务内,保证不会出现"已提交但事件丢失"
+type OutboxEvent struct {
+	ID            uint   `gorm:"primarykey"`
+	EventType     string `gorm:"size:64;not null"`
+	Subject       string `gorm:"size:64;not null"`
+	Payload       []byte
+	Attempts      int
+	LastError     string `gorm:"size:1024"`
+	LastAttemptAt *time.Time
+	PublishedAt   *time.Time
+	CreatedAt     time.Time
+}
+
+// enqueueOutboxEvent 在tx内写入一条待发布事件,event在入队时即完整构造,
+// 保证Payload中的CloudEvent.Time反映变更真正发生的时刻,而不是将来投递的时刻
+func enqueueOutboxEvent(tx *gorm.DB, event events.CloudEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&OutboxEvent{EventType: event.Type, Subject: event.Subject, Payload: payload}).Error
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ZZYYG/aicr-test-medium-repo/src/events"
+)
+
+// maxOutboxAttempts 是单个事件在被放弃前允许的最大投递尝试次数(含首次投递)
+const maxOutboxAttempts = 10
+
+// OutboxDispatcher 周期性轮询outbox表,把未发布的事件经Publisher投递出去,
+// 失败的事件按指数退避重试,实现at-least-once投递语义
+type OutboxDispatcher struct {
+	db        *gorm.DB
+	publisher events.Publisher
+	interval  time.Duration
+}
+
+// NewOutboxDispatcher 创建一个按interval轮询的outbox分发器
+func NewOutboxDispatcher(db *gorm.DB, publisher events.Publisher, interval time.Duration) *OutboxDispatcher {
+	return &OutboxDispatcher{db: db, publisher: publisher, interval: interval}
+}
+
+// Run阻塞轮询直到ctx被取消
+func (d *OutboxDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.drainOnce(ctx)
+		}
+	}
+}
+
+func (d *OutboxDispatcher) drainOnce(ctx context.Context) {
+	var pending []OutboxEvent
+	err := d.db.Where("published_at IS NULL AND attempts < ?", maxOutboxAttempts).
+		Order("id").Limit(100).Find(&pending).Error
+	if err != nil {
+		return
+	}
+
+	for _, outboxEvt := range pending {
+		if backoffElapsed(outboxEvt) {
+			d.deliver(ctx, outboxEvt)
+		}
+	}
+}
+
+// backoffElapsed判断距离上次失败是否已经过了对应尝试次数的退避时间。必须用LastAttemptAt而不是CreatedAt计算,
+// 否则一旦事件存在时间超过了退避上限(1分钟),每次轮询都会认为退避已过,指数退避间隔形同虚设
+func backoffElapsed(evt OutboxEvent) bool {
+	if evt.Attempts == 0 || evt.LastAttemptAt == nil {
+		return true
+	}
+	return time.Since(*evt.LastAttemptAt) >= backoffFor(evt.Attempts)
+}
+
+func backoffFor(attempts int) time.Duration {
+	backoff := time.Duration(1<<uint(attempts)) * time.Second
+	if backoff > time.Minute {
+		return time.Minute
+	}
+	return backoff
+}
+
+func (d *OutboxDispatcher) deliver(ctx context.Context, outboxEvt OutboxEvent) {
+	var event events.CloudEvent
+	if err := json.Unmarshal(outboxEvt.Payload, &event); err != nil {
+		d.markFailed(outboxEvt, err)
+		return
+	}
+
+	if err := d.publisher.Publish(ctx, event); err != nil {
+		d.markFailed(outboxEvt, err)
+		return
+	}
+
+	now := time.Now()
+	d.db.Model(&OutboxEvent{}).Where("id = ?", outboxEvt.ID).Updates(map[string]interface{}{"published_at": now})
+}
+
+func (d *OutboxDispatcher) markFailed(outboxEvt OutboxEvent, err error) {
+	d.db.Model(&OutboxEvent{}).Where("id = ?", outboxEvt.ID).Updates(map[string]interface{}{
+		"attempts":        outboxEvt.Attempts + 1,
+		"last_error":      err.Error(),
+		"last_attempt_at": time.Now(),
+	})
+}